@@ -0,0 +1,25 @@
+package main
+
+import (
+  "log"
+
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
+  "github.com/nokia/danm/pkg/netadmit"
+  "k8s.io/client-go/rest"
+)
+
+//main wires up the API client the admission webhook's validators need to look up existing
+//ClusterNetworks/TenantNetworks (see validateNetworkOverlaps). The HTTPS listener and TLS
+//termination for the admission webhook itself are provided by the deployment-specific entrypoint
+//and are out of scope here
+func main() {
+  config, err := rest.InClusterConfig()
+  if err != nil {
+    log.Fatalf("Could not create in-cluster Kubernetes client config: %v", err)
+  }
+  danmClient, err := danmclientset.NewForConfig(config)
+  if err != nil {
+    log.Fatalf("Could not create DANM API clientset: %v", err)
+  }
+  netadmit.SetDanmClient(danmClient)
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+  metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+//DanmNet is the schema of the DanmNet, ClusterNetwork and TenantNetwork CRDs. All three APIs share
+//this same representation on the wire, they only differ in scope (namespaced vs. cluster-wide) and
+//in which of the Options are allowed to be set by validators.go
+type DanmNet struct {
+  metav1.TypeMeta   `json:",inline"`
+  metav1.ObjectMeta `json:"metadata,omitempty"`
+  Spec              DanmNetSpec   `json:"spec"`
+  Status            DanmNetStatus `json:"status,omitempty"`
+}
+
+type DanmNetSpec struct {
+  NetworkID      string        `json:"NetworkID"`
+  Options        DanmNetOption `json:"Options,omitempty"`
+  AllowedTenants []string      `json:"AllowedTenants,omitempty"`
+}
+
+type DanmNetOption struct {
+  Cidr       string            `json:"cidr,omitempty"`
+  Net6       string            `json:"net6,omitempty"`
+  Pool       IpPool            `json:"allocation_pool,omitempty"`
+  Pool6      IpPool            `json:"allocation_pool_v6,omitempty"`
+  Alloc      string            `json:"alloc,omitempty"`
+  Alloc6     string            `json:"alloc6,omitempty"`
+  Exclusions []Range           `json:"exclude,omitempty"`
+  Routes     map[string]string `json:"routes,omitempty"`
+  Routes6    map[string]string `json:"routes6,omitempty"`
+  Gateway    string            `json:"gateway,omitempty"`
+  Gateway6   string            `json:"gateway6,omitempty"`
+  NoGateway  bool              `json:"no_gateway,omitempty"`
+  Device     string            `json:"host_device,omitempty"`
+  Vlan       int               `json:"vlan,omitempty"`
+  Vxlan      int               `json:"vxlan,omitempty"`
+  VlanRange  string            `json:"vlan_range,omitempty"`
+  VxlanRange string            `json:"vxlan_range,omitempty"`
+}
+
+//IpPool describes the boundaries of the address range DANM's IPAM is allowed to allocate from,
+//shared shape for both the IPv4 (Pool) and IPv6 (Pool6) address families
+type IpPool struct {
+  Start string `json:"start,omitempty"`
+  End   string `json:"end,omitempty"`
+}
+
+//Range describes an inclusive IP address interval, used for allocation pool Exclusions
+type Range struct {
+  StartIP string `json:"start_ip,omitempty"`
+  EndIP   string `json:"end_ip,omitempty"`
+}
+
+//VidRange describes an inclusive interval of VLAN or VxLAN tags
+type VidRange struct {
+  Start uint32 `json:"start"`
+  End   uint32 `json:"end"`
+}
+
+type DanmNetStatus struct {
+  Vlans      []VidRange `json:"vlans,omitempty"`
+  Vxlans     []VidRange `json:"vxlans,omitempty"`
+  VlanAlloc  string     `json:"vlan_alloc,omitempty"`
+  VxlanAlloc string     `json:"vxlan_alloc,omitempty"`
+}
+
+type DanmNetList struct {
+  metav1.TypeMeta `json:",inline"`
+  metav1.ListMeta `json:"metadata,omitempty"`
+  Items           []DanmNet `json:"items"`
+}
+
+//ClusterNetwork and TenantNetwork are separate CRDs from DanmNet, but they are admitted through the
+//same webhook and validated with the same Validator functions, so they share DanmNet's Go representation
+type (
+  ClusterNetwork     = DanmNet
+  ClusterNetworkList = DanmNetList
+  TenantNetwork      = DanmNet
+  TenantNetworkList  = DanmNetList
+)
@@ -0,0 +1,30 @@
+package versioned
+
+import (
+  danmv1 "github.com/nokia/danm/crd/apis/danm/v1"
+  metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//Interface is the entry point every consumer (e.g. pkg/netadmit) programs against, so the concrete
+//implementation can be swapped out in tests
+type Interface interface {
+  DanmV1() DanmV1Interface
+}
+
+type DanmV1Interface interface {
+  ClusterNetworks() ClusterNetworkInterface
+  TenantNetworks(namespace string) TenantNetworkInterface
+  DanmNets(namespace string) DanmNetInterface
+}
+
+type ClusterNetworkInterface interface {
+  List(opts metav1.ListOptions) (*danmv1.ClusterNetworkList, error)
+}
+
+type TenantNetworkInterface interface {
+  List(opts metav1.ListOptions) (*danmv1.TenantNetworkList, error)
+}
+
+type DanmNetInterface interface {
+  List(opts metav1.ListOptions) (*danmv1.DanmNetList, error)
+}
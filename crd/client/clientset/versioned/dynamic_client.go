@@ -0,0 +1,114 @@
+package versioned
+
+import (
+  "context"
+
+  danmv1 "github.com/nokia/danm/crd/apis/danm/v1"
+  metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+  "k8s.io/apimachinery/pkg/runtime"
+  "k8s.io/apimachinery/pkg/runtime/schema"
+  "k8s.io/client-go/dynamic"
+  "k8s.io/client-go/rest"
+)
+
+var (
+  clusterNetworkResource = schema.GroupVersionResource{Group: "danm.k8s.io", Version: "v1", Resource: "clusternetworks"}
+  tenantNetworkResource  = schema.GroupVersionResource{Group: "danm.k8s.io", Version: "v1", Resource: "tenantnetworks"}
+  danmNetResource        = schema.GroupVersionResource{Group: "danm.k8s.io", Version: "v1", Resource: "danmnets"}
+)
+
+//Clientset is a thin typed wrapper around the dynamic client, converting unstructured API objects
+//into the DanmNet-shaped Go types the validators operate on
+type Clientset struct {
+  dynamicClient dynamic.Interface
+}
+
+//NewForConfig builds a Clientset from the given Kubernetes REST config
+func NewForConfig(config *rest.Config) (*Clientset, error) {
+  dynamicClient, err := dynamic.NewForConfig(config)
+  if err != nil {
+    return nil, err
+  }
+  return &Clientset{dynamicClient: dynamicClient}, nil
+}
+
+func (c *Clientset) DanmV1() DanmV1Interface {
+  return &danmV1Client{dynamicClient: c.dynamicClient}
+}
+
+type danmV1Client struct {
+  dynamicClient dynamic.Interface
+}
+
+func (c *danmV1Client) ClusterNetworks() ClusterNetworkInterface {
+  return &clusterNetworkClient{resource: c.dynamicClient.Resource(clusterNetworkResource)}
+}
+
+func (c *danmV1Client) TenantNetworks(namespace string) TenantNetworkInterface {
+  return &tenantNetworkClient{resource: c.dynamicClient.Resource(tenantNetworkResource).Namespace(namespace)}
+}
+
+func (c *danmV1Client) DanmNets(namespace string) DanmNetInterface {
+  return &danmNetClient{resource: c.dynamicClient.Resource(danmNetResource).Namespace(namespace)}
+}
+
+type clusterNetworkClient struct {
+  resource dynamic.NamespaceableResourceInterface
+}
+
+func (c *clusterNetworkClient) List(opts metav1.ListOptions) (*danmv1.ClusterNetworkList, error) {
+  raw, err := c.resource.List(context.TODO(), opts)
+  if err != nil {
+    return nil, err
+  }
+  items, err := toDanmNets(raw)
+  if err != nil {
+    return nil, err
+  }
+  return &danmv1.ClusterNetworkList{Items: items}, nil
+}
+
+type tenantNetworkClient struct {
+  resource dynamic.ResourceInterface
+}
+
+func (c *tenantNetworkClient) List(opts metav1.ListOptions) (*danmv1.TenantNetworkList, error) {
+  raw, err := c.resource.List(context.TODO(), opts)
+  if err != nil {
+    return nil, err
+  }
+  items, err := toDanmNets(raw)
+  if err != nil {
+    return nil, err
+  }
+  return &danmv1.TenantNetworkList{Items: items}, nil
+}
+
+type danmNetClient struct {
+  resource dynamic.ResourceInterface
+}
+
+func (c *danmNetClient) List(opts metav1.ListOptions) (*danmv1.DanmNetList, error) {
+  raw, err := c.resource.List(context.TODO(), opts)
+  if err != nil {
+    return nil, err
+  }
+  items, err := toDanmNets(raw)
+  if err != nil {
+    return nil, err
+  }
+  return &danmv1.DanmNetList{Items: items}, nil
+}
+
+func toDanmNets(list *unstructured.UnstructuredList) ([]danmv1.DanmNet, error) {
+  items := make([]danmv1.DanmNet, 0, len(list.Items))
+  for _, raw := range list.Items {
+    var net danmv1.DanmNet
+    if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw.Object, &net); err != nil {
+      return nil, err
+    }
+    items = append(items, net)
+  }
+  return items, nil
+}
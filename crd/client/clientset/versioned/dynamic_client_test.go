@@ -0,0 +1,36 @@
+package versioned
+
+import (
+  "testing"
+
+  "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestToDanmNets(t *testing.T) {
+  list := &unstructured.UnstructuredList{
+    Items: []unstructured.Unstructured{
+      {
+        Object: map[string]interface{}{
+          "metadata": map[string]interface{}{"name": "net-a", "namespace": "default"},
+          "spec": map[string]interface{}{
+            "NetworkID": "net-a",
+            "Options":   map[string]interface{}{"cidr": "10.0.0.0/24"},
+          },
+        },
+      },
+    },
+  }
+  nets, err := toDanmNets(list)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(nets) != 1 {
+    t.Fatalf("expected 1 converted DanmNet, got %d", len(nets))
+  }
+  if nets[0].ObjectMeta.Name != "net-a" {
+    t.Errorf("expected name net-a, got %s", nets[0].ObjectMeta.Name)
+  }
+  if nets[0].Spec.Options.Cidr != "10.0.0.0/24" {
+    t.Errorf("expected CIDR 10.0.0.0/24, got %s", nets[0].Spec.Options.Cidr)
+  }
+}
@@ -0,0 +1,52 @@
+package ipam
+
+import (
+  "encoding/base64"
+)
+
+//Bitmask tracks, one bit per address, which addresses of an allocation pool are already taken.
+//It is persisted on the DanmNet CR as a base64 string (Spec.Options.Alloc / Alloc6)
+type Bitmask []byte
+
+//NewBitmask allocates a zeroed out Bitmask big enough to hold the given number of addresses
+func NewBitmask(numAddresses int) Bitmask {
+  return make(Bitmask, (numAddresses+7)/8)
+}
+
+//DecodeBitmask restores a Bitmask from its persisted, base64-encoded form. An empty string decodes
+//to a freshly allocated, all-free Bitmask
+func DecodeBitmask(encoded string, numAddresses int) (Bitmask, error) {
+  if encoded == "" {
+    return NewBitmask(numAddresses), nil
+  }
+  decoded, err := base64.StdEncoding.DecodeString(encoded)
+  if err != nil {
+    return nil, err
+  }
+  if len(decoded) < (numAddresses+7)/8 {
+    grown := NewBitmask(numAddresses)
+    copy(grown, decoded)
+    decoded = grown
+  }
+  return Bitmask(decoded), nil
+}
+
+//Encode persists the Bitmask into the base64 string representation stored on the CR
+func (b Bitmask) Encode() string {
+  return base64.StdEncoding.EncodeToString(b)
+}
+
+//Set marks the address at the given offset as taken
+func (b Bitmask) Set(bit int) {
+  b[bit/8] |= 1 << uint(bit%8)
+}
+
+//Clear marks the address at the given offset as free
+func (b Bitmask) Clear(bit int) {
+  b[bit/8] &^= 1 << uint(bit%8)
+}
+
+//IsSet returns whether the address at the given offset is currently taken
+func (b Bitmask) IsSet(bit int) bool {
+  return b[bit/8]&(1<<uint(bit%8)) != 0
+}
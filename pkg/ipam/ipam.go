@@ -0,0 +1,58 @@
+package ipam
+
+import (
+  "encoding/binary"
+  "errors"
+  "math/big"
+  "net"
+  "strconv"
+)
+
+//MaxV6PoolSize is the largest number of addresses an IPv6 allocation pool may span. The allocation
+//bitmask holds one bit per address, and a full /64 (the smallest subnet auto-assigned by v6 SLAAC
+//conventions) would require a 2^64 bit bitmask, so pool sizes are capped well below that to keep the
+//bitmask representable in memory
+const MaxV6PoolSize = 1 << 20
+
+//PoolSize6 returns the number of addresses spanned by poolStart..poolEnd (inclusive), or an error if
+//the range is inverted or too large for the per-address allocation bitmask to represent
+func PoolSize6(poolStart, poolEnd net.IP) (int, error) {
+  span := new(big.Int).Sub(Ip2int6(poolEnd), Ip2int6(poolStart))
+  if span.Sign() < 0 {
+    return 0, errors.New("IPv6 allocation pool end is smaller than its start")
+  }
+  count := new(big.Int).Add(span, big.NewInt(1))
+  if count.Cmp(big.NewInt(MaxV6PoolSize)) > 0 {
+    return 0, errors.New("IPv6 allocation pool spans more than the allowed " + strconv.Itoa(MaxV6PoolSize) + " addresses, please set a smaller allocation_pool_v6")
+  }
+  return int(count.Int64()), nil
+}
+
+//Ip2int converts an IPv4 address into its numeric representation
+func Ip2int(ip net.IP) uint32 {
+  if v4 := ip.To4(); v4 != nil {
+    return binary.BigEndian.Uint32(v4)
+  }
+  return binary.BigEndian.Uint32(ip)
+}
+
+//Int2ip converts a numeric representation back into an IPv4 address
+func Int2ip(nn uint32) net.IP {
+  ip := make(net.IP, 4)
+  binary.BigEndian.PutUint32(ip, nn)
+  return ip
+}
+
+//Ip2int6 converts an IPv6 address into its numeric representation. A big.Int is used because a
+//128 bit address does not fit into any native Go integer type
+func Ip2int6(ip net.IP) *big.Int {
+  return new(big.Int).SetBytes(ip.To16())
+}
+
+//Int2ip6 converts a numeric representation back into an IPv6 address
+func Int2ip6(nn *big.Int) net.IP {
+  raw := nn.Bytes()
+  ip := make(net.IP, 16)
+  copy(ip[16-len(raw):], raw)
+  return ip
+}
@@ -0,0 +1,67 @@
+package ipam
+
+import (
+  "net"
+  "testing"
+)
+
+func TestIp2int6RoundTrip(t *testing.T) {
+  cases := []string{"fd00::1", "2001:db8::ffff", "::1"}
+  for _, ipStr := range cases {
+    ip := net.ParseIP(ipStr)
+    got := Int2ip6(Ip2int6(ip))
+    if !got.Equal(ip) {
+      t.Errorf("Ip2int6/Int2ip6 round trip failed for %s, got %s", ipStr, got)
+    }
+  }
+}
+
+func TestBitmaskSetIsSet(t *testing.T) {
+  b := NewBitmask(20)
+  if b.IsSet(5) {
+    t.Fatal("bit 5 should not be set on a fresh Bitmask")
+  }
+  b.Set(5)
+  if !b.IsSet(5) {
+    t.Fatal("bit 5 should be set after Set(5)")
+  }
+  b.Clear(5)
+  if b.IsSet(5) {
+    t.Fatal("bit 5 should be unset after Clear(5)")
+  }
+}
+
+func TestPoolSize6RejectsOversizedPool(t *testing.T) {
+  //fd00::2 .. fd00::ffff:ffff:ffff:fffe spans nearly the whole of a /64 Net6, which is far larger
+  //than a per-address bitmask can represent
+  start := net.ParseIP("fd00::2")
+  end := net.ParseIP("fd00::ffff:ffff:ffff:fffe")
+  if _, err := PoolSize6(start, end); err == nil {
+    t.Fatal("expected an error for a pool spanning a full /64")
+  }
+}
+
+func TestPoolSize6AcceptsBoundedPool(t *testing.T) {
+  start := net.ParseIP("fd00::2")
+  end := net.ParseIP("fd00::100")
+  size, err := PoolSize6(start, end)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if size != 255 {
+    t.Errorf("expected pool size 255, got %d", size)
+  }
+}
+
+func TestDecodeBitmaskRoundTrip(t *testing.T) {
+  b := NewBitmask(10)
+  b.Set(3)
+  encoded := b.Encode()
+  decoded, err := DecodeBitmask(encoded, 10)
+  if err != nil {
+    t.Fatalf("unexpected error decoding bitmask: %v", err)
+  }
+  if !decoded.IsSet(3) {
+    t.Fatal("decoded bitmask lost bit 3")
+  }
+}
@@ -0,0 +1,79 @@
+package ipam
+
+import (
+  "errors"
+  "math/big"
+  "net"
+
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+//ReserveExclusions pre-marks the bits corresponding to netInfo.Spec.Options.Exclusions in the
+//IPv4 and/or IPv6 allocation bitmask (Alloc/Alloc6), recording those addresses as taken. Exclusions are
+//dispatched to the IPv4 or IPv6 bitmask based on which address family their StartIP belongs to
+func ReserveExclusions(netInfo *danmtypes.DanmNet, exclusions []danmtypes.Range) error {
+  for _, excl := range exclusions {
+    start := net.ParseIP(excl.StartIP)
+    end := net.ParseIP(excl.EndIP)
+    if start == nil || end == nil {
+      return errors.New("Exclusion range:" + excl.StartIP + "-" + excl.EndIP + " contains an invalid IP address")
+    }
+    if start.To4() != nil {
+      if err := reserveV4Range(netInfo, start, end); err != nil {
+        return err
+      }
+      continue
+    }
+    if err := reserveV6Range(netInfo, start, end); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func reserveV4Range(netInfo *danmtypes.DanmNet, start, end net.IP) error {
+  poolStart := net.ParseIP(netInfo.Spec.Options.Pool.Start)
+  poolEnd := net.ParseIP(netInfo.Spec.Options.Pool.End)
+  if poolStart == nil || poolEnd == nil {
+    return errors.New("Cannot reserve an address, allocation pool is not defined")
+  }
+  numAddresses := int(Ip2int(poolEnd)-Ip2int(poolStart)) + 1
+  bitmask, err := DecodeBitmask(netInfo.Spec.Options.Alloc, numAddresses)
+  if err != nil {
+    return err
+  }
+  first := int(Ip2int(start) - Ip2int(poolStart))
+  last := int(Ip2int(end) - Ip2int(poolStart))
+  for bit := first; bit <= last; bit++ {
+    if bit >= 0 && bit < numAddresses {
+      bitmask.Set(bit)
+    }
+  }
+  netInfo.Spec.Options.Alloc = bitmask.Encode()
+  return nil
+}
+
+func reserveV6Range(netInfo *danmtypes.DanmNet, start, end net.IP) error {
+  poolStart := net.ParseIP(netInfo.Spec.Options.Pool6.Start)
+  poolEnd := net.ParseIP(netInfo.Spec.Options.Pool6.End)
+  if poolStart == nil || poolEnd == nil {
+    return errors.New("Cannot reserve an address, IPv6 allocation pool is not defined")
+  }
+  numAddresses, err := PoolSize6(poolStart, poolEnd)
+  if err != nil {
+    return err
+  }
+  bitmask, err := DecodeBitmask(netInfo.Spec.Options.Alloc6, numAddresses)
+  if err != nil {
+    return err
+  }
+  first := int(new(big.Int).Sub(Ip2int6(start), Ip2int6(poolStart)).Int64())
+  last := int(new(big.Int).Sub(Ip2int6(end), Ip2int6(poolStart)).Int64())
+  for bit := first; bit <= last; bit++ {
+    if bit >= 0 && bit < numAddresses {
+      bitmask.Set(bit)
+    }
+  }
+  netInfo.Spec.Options.Alloc6 = bitmask.Encode()
+  return nil
+}
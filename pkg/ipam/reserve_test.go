@@ -0,0 +1,25 @@
+package ipam
+
+import (
+  "testing"
+
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+//TestReserveExclusionsRejectsOversizedV6Pool guards against ReserveExclusions silently no-opping
+//when the IPv6 allocation pool is too large for the allocation bitmask to represent (e.g. a pool
+//spanning nearly a whole /64), instead of materializing a truncated bitmask that reserves nothing
+func TestReserveExclusionsRejectsOversizedV6Pool(t *testing.T) {
+  netInfo := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      Options: danmtypes.DanmNetOption{
+        Net6:  "fd00::/64",
+        Pool6: danmtypes.IpPool{Start: "fd00::2", End: "fd00::ffff:ffff:ffff:fffe"},
+      },
+    },
+  }
+  exclusions := []danmtypes.Range{{StartIP: "fd00::10", EndIP: "fd00::20"}}
+  if err := ReserveExclusions(netInfo, exclusions); err == nil {
+    t.Fatal("expected an error reserving an exclusion range in an oversized IPv6 pool")
+  }
+}
@@ -0,0 +1,85 @@
+package ipam
+
+import (
+  "errors"
+
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+//vidPoolSize returns the total number of VIDs covered by ranges, i.e. the number of bits the
+//allocation bitmask backing them needs to hold
+func vidPoolSize(ranges []danmtypes.VidRange) int {
+  var total int
+  for _, r := range ranges {
+    total += int(r.End-r.Start) + 1
+  }
+  return total
+}
+
+//vidToBit maps a VID to its bit offset within the bitmask built over ranges. Ranges are assumed
+//sorted and non-overlapping, as guaranteed by the webhook's parseVidRange
+func vidToBit(ranges []danmtypes.VidRange, vid uint32) (int, error) {
+  offset := 0
+  for _, r := range ranges {
+    if vid >= r.Start && vid <= r.End {
+      return offset + int(vid-r.Start), nil
+    }
+    offset += int(r.End-r.Start) + 1
+  }
+  return 0, errors.New("VID is not part of the allocated VlanRange/VxlanRange")
+}
+
+//bitToVid is the inverse of vidToBit
+func bitToVid(ranges []danmtypes.VidRange, bit int) (uint32, error) {
+  offset := 0
+  for _, r := range ranges {
+    size := int(r.End-r.Start) + 1
+    if bit < offset+size {
+      return r.Start + uint32(bit-offset), nil
+    }
+    offset += size
+  }
+  return 0, errors.New("bit offset falls outside of the allocated VlanRange/VxlanRange")
+}
+
+//AllocateVid hands out the first free VID from ranges (a TenantNetwork or ClusterNetwork's parsed
+//Status.Vlans/Vxlans), so the CNI backend can give distinct tags to pods sharing the same network.
+//encoded is the network's persisted Status.VlanAlloc/VxlanAlloc bitmask; AllocateVid returns the
+//picked VID together with the bitmask's updated, base64-encoded form to persist back onto the CR
+func AllocateVid(encoded string, ranges []danmtypes.VidRange) (uint32, string, error) {
+  numVids := vidPoolSize(ranges)
+  if numVids == 0 {
+    return 0, "", errors.New("cannot allocate a VID, no VlanRange/VxlanRange is defined")
+  }
+  bitmask, err := DecodeBitmask(encoded, numVids)
+  if err != nil {
+    return 0, "", err
+  }
+  for bit := 0; bit < numVids; bit++ {
+    if !bitmask.IsSet(bit) {
+      bitmask.Set(bit)
+      vid, err := bitToVid(ranges, bit)
+      if err != nil {
+        return 0, "", err
+      }
+      return vid, bitmask.Encode(), nil
+    }
+  }
+  return 0, "", errors.New("no free VID is left in the allocated VlanRange/VxlanRange")
+}
+
+//ReleaseVid clears vid's bit in encoded, returning the updated, base64-encoded allocation bitmask,
+//so a detached pod's tag becomes available for reuse by a later AllocateVid
+func ReleaseVid(encoded string, ranges []danmtypes.VidRange, vid uint32) (string, error) {
+  numVids := vidPoolSize(ranges)
+  bitmask, err := DecodeBitmask(encoded, numVids)
+  if err != nil {
+    return "", err
+  }
+  bit, err := vidToBit(ranges, vid)
+  if err != nil {
+    return "", err
+  }
+  bitmask.Clear(bit)
+  return bitmask.Encode(), nil
+}
@@ -0,0 +1,48 @@
+package ipam
+
+import (
+  "testing"
+
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+func TestAllocateVidPicksDistinctTags(t *testing.T) {
+  ranges := []danmtypes.VidRange{{Start: 100, End: 101}}
+  first, encoded, err := AllocateVid("", ranges)
+  if err != nil {
+    t.Fatalf("unexpected error on first allocation: %v", err)
+  }
+  second, _, err := AllocateVid(encoded, ranges)
+  if err != nil {
+    t.Fatalf("unexpected error on second allocation: %v", err)
+  }
+  if first == second {
+    t.Fatalf("expected two pods on the same network to get distinct tags, both got %d", first)
+  }
+}
+
+func TestAllocateVidRejectsExhaustedPool(t *testing.T) {
+  ranges := []danmtypes.VidRange{{Start: 100, End: 100}}
+  _, encoded, err := AllocateVid("", ranges)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if _, _, err := AllocateVid(encoded, ranges); err == nil {
+    t.Fatal("expected an error allocating from an exhausted single-tag pool")
+  }
+}
+
+func TestReleaseVidFreesTagForReuse(t *testing.T) {
+  ranges := []danmtypes.VidRange{{Start: 100, End: 100}}
+  vid, encoded, err := AllocateVid("", ranges)
+  if err != nil {
+    t.Fatalf("unexpected error allocating: %v", err)
+  }
+  encoded, err = ReleaseVid(encoded, ranges, vid)
+  if err != nil {
+    t.Fatalf("unexpected error releasing: %v", err)
+  }
+  if _, _, err := AllocateVid(encoded, ranges); err != nil {
+    t.Fatalf("expected the released tag to be allocatable again, got error: %v", err)
+  }
+}
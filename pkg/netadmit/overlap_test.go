@@ -0,0 +1,90 @@
+package netadmit
+
+import (
+  "testing"
+
+  metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+func TestCidrsOverlap(t *testing.T) {
+  cases := []struct {
+    a, b     string
+    expected bool
+  }{
+    {"10.0.0.0/24", "10.0.0.0/16", true},
+    {"10.0.0.0/24", "10.1.0.0/24", false},
+    {"", "10.0.0.0/24", false},
+    {"10.0.0.0/24", "10.0.0.0/24", true},
+  }
+  for _, c := range cases {
+    if got := cidrsOverlap(c.a, c.b); got != c.expected {
+      t.Errorf("cidrsOverlap(%q, %q) = %v, want %v", c.a, c.b, got, c.expected)
+    }
+  }
+}
+
+func TestCheckNetworkOverlapAllowsSameL2(t *testing.T) {
+  newManifest := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "new-net"},
+    Spec:       danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Cidr: "10.0.0.0/24", Device: "eth0", Vlan: 100}},
+  }
+  other := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "other-net"},
+    Spec:       danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Cidr: "10.0.0.0/24", Device: "eth0", Vlan: 100}},
+  }
+  if err := checkNetworkOverlap(newManifest, other, "ClusterNetwork"); err != nil {
+    t.Fatalf("expected no error for two networks sharing the same Device+Vlan L2 segment, got: %v", err)
+  }
+}
+
+//TestCheckNetworkOverlapIgnoresDefaultRoute guards against a legacy 0.0.0.0/0 Routes entry (the
+//pre-Gateway way of expressing a default gateway) being treated as overlapping every other network
+//that has a CIDR
+func TestCheckNetworkOverlapIgnoresDefaultRoute(t *testing.T) {
+  newManifest := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "new-net"},
+    Spec: danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{
+      Cidr:   "10.1.0.0/24",
+      Routes: map[string]string{"0.0.0.0/0": "10.1.0.1"},
+    }},
+  }
+  other := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "other-net"},
+    Spec:       danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Cidr: "10.2.0.0/24"}},
+  }
+  if err := checkNetworkOverlap(newManifest, other, "ClusterNetwork"); err != nil {
+    t.Fatalf("expected a default route to never be treated as overlapping, got: %v", err)
+  }
+}
+
+func TestIsDefaultRoute(t *testing.T) {
+  cases := []struct {
+    dest     string
+    expected bool
+  }{
+    {"0.0.0.0/0", true},
+    {"::/0", true},
+    {"10.0.0.0/24", false},
+    {"not-a-cidr", false},
+  }
+  for _, c := range cases {
+    if got := isDefaultRoute(c.dest); got != c.expected {
+      t.Errorf("isDefaultRoute(%q) = %v, want %v", c.dest, got, c.expected)
+    }
+  }
+}
+
+func TestCheckNetworkOverlapRejectsDifferentL2(t *testing.T) {
+  newManifest := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "new-net"},
+    Spec:       danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Cidr: "10.0.0.0/24", Device: "eth0", Vlan: 100}},
+  }
+  other := &danmtypes.DanmNet{
+    ObjectMeta: metav1.ObjectMeta{Name: "other-net"},
+    Spec:       danmtypes.DanmNetSpec{Options: danmtypes.DanmNetOption{Cidr: "10.0.0.0/16", Device: "eth1", Vlan: 200}},
+  }
+  if err := checkNetworkOverlap(newManifest, other, "ClusterNetwork"); err == nil {
+    t.Fatal("expected an error for overlapping CIDRs on different L2 segments")
+  }
+}
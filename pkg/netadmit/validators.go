@@ -4,8 +4,14 @@ import (
   "errors"
   "net"
   "encoding/binary"
+  "math/big"
+  "sort"
+  "strconv"
+  "strings"
   admissionv1 "k8s.io/api/admission/v1beta1"
+  metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
   danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+  danmclientset "github.com/nokia/danm/crd/client/clientset/versioned"
   "github.com/nokia/danm/pkg/ipam"
 )
 
@@ -16,19 +22,31 @@ type ValidatorMapping []Validator
 
 const (
   MaxNidLength = 12
+  MinVlanId = 1
+  MaxVlanId = 4094
+  MinVxlanId = 1
+  MaxVxlanId = 16777215
 )
 
 var (
-  DanmNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateVids,validateNetworkId,validateAbsenceOfAllowedTenants}
-  ClusterNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateVids,validateNetworkId}
-  TenantNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateNetworkId,validateAbsenceOfAllowedTenants,validateTenantNetRules}
+  DanmNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateAllocationPoolV6,validateExclusions,validateGateway,validateVids,validateNetworkId,validateAbsenceOfAllowedTenants}
+  ClusterNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateAllocationPoolV6,validateExclusions,validateGateway,validateVids,validateNetworkId,validateNetworkOverlaps}
+  TenantNetMapping = []Validator{validateIpv4Fields,validateIpv6Fields,validateAllocationPool,validateAllocationPoolV6,validateExclusions,validateGateway,validateVids,validateNetworkId,validateAbsenceOfAllowedTenants,validateTenantNetRules,validateNetworkOverlaps}
   danmValidationConfig = map[string]ValidatorMapping {
     "DanmNet": DanmNetMapping,
     "ClusterNetwork": ClusterNetMapping,
     "TenantNetwork": TenantNetMapping,
   }
+  //danmClient is used by validators that need to look up sibling networks in the API, e.g. validateNetworkOverlaps
+  danmClient danmclientset.Interface
 )
 
+//SetDanmClient injects the clientset the overlap validators use to list existing ClusterNetworks/TenantNetworks.
+//It must be called once during webhook startup, before any admission request is served.
+func SetDanmClient(client danmclientset.Interface) {
+  danmClient = client
+}
+
 func validateIpv4Fields(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
   return validateIpFields(newManifest.Spec.Options.Cidr, newManifest.Spec.Options.Routes)
 }
@@ -72,7 +90,14 @@ func validateAllocationPool(oldManifest, newManifest *danmtypes.DanmNet, opType
     return errors.New("Invalid CIDR parameter: " + cidr)
   }
   if newManifest.Spec.Options.Pool.Start == "" {
-    newManifest.Spec.Options.Pool.Start = (ipam.Int2ip(ipam.Ip2int(ipnet.IP) + 1)).String()
+    start := ipam.Ip2int(ipnet.IP) + 1
+    explicitGwAtDefault := newManifest.Spec.Options.Gateway != "" && newManifest.Spec.Options.Gateway == ipam.Int2ip(start).String()
+    if (newManifest.Spec.Options.Gateway == "" && !newManifest.Spec.Options.NoGateway) || explicitGwAtDefault {
+      //network+1 is claimed by the default Gateway, or by an explicit Gateway that happens to sit
+      //at the same address; either way reserve it so it is never handed out by IPAM
+      start++
+    }
+    newManifest.Spec.Options.Pool.Start = (ipam.Int2ip(start)).String()
   }
   if newManifest.Spec.Options.Pool.End == "" {
     newManifest.Spec.Options.Pool.End = (ipam.Int2ip(ipam.Ip2int(GetBroadcastAddress(ipnet)) - 1)).String()
@@ -93,15 +118,324 @@ func GetBroadcastAddress(subnet *net.IPNet) (net.IP) {
   return ip
 }
 
+func validateAllocationPoolV6(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
+  if opType == admissionv1.Create && newManifest.Spec.Options.Alloc6 != "" {
+    return errors.New("Allocation bitmask shall not be manually defined upon creation!")
+  }
+  net6 := newManifest.Spec.Options.Net6
+  if net6 == "" {
+    if newManifest.Spec.Options.Pool6.Start != "" || newManifest.Spec.Options.Pool6.End != "" {
+      return errors.New("Allocation pool cannot be defined without Net6!")
+    }
+    return nil
+  }
+  _, ipnet, err := net.ParseCIDR(net6)
+  if err != nil {
+    return errors.New("Invalid Net6 parameter: " + net6)
+  }
+  if newManifest.Spec.Options.Pool6.Start == "" {
+    start := new(big.Int).Add(ipam.Ip2int6(ipnet.IP), big.NewInt(1))
+    explicitGwAtDefault := newManifest.Spec.Options.Gateway6 != "" && newManifest.Spec.Options.Gateway6 == ipam.Int2ip6(start).String()
+    if (newManifest.Spec.Options.Gateway6 == "" && !newManifest.Spec.Options.NoGateway) || explicitGwAtDefault {
+      //net6+1 is claimed by the default Gateway6, or by an explicit Gateway6 that happens to sit at
+      //the same address; either way reserve it so it is never handed out by IPAM
+      start.Add(start, big.NewInt(1))
+    }
+    newManifest.Spec.Options.Pool6.Start = ipam.Int2ip6(start).String()
+  }
+  if newManifest.Spec.Options.Pool6.End == "" {
+    //the allocation bitmask holds one bit per address, so a defaulted pool must stay within
+    //MaxV6PoolSize even though Net6 itself can be a much wider /64 - cap the default window instead
+    //of handing out the whole /64 and having PoolSize6 reject it below
+    rangeEnd := new(big.Int).Sub(ipam.Ip2int6(GetV6RangeEnd(ipnet)), big.NewInt(1))
+    boundedEnd := new(big.Int).Add(ipam.Ip2int6(net.ParseIP(newManifest.Spec.Options.Pool6.Start)), big.NewInt(ipam.MaxV6PoolSize-1))
+    if boundedEnd.Cmp(rangeEnd) > 0 {
+      boundedEnd = rangeEnd
+    }
+    newManifest.Spec.Options.Pool6.End = ipam.Int2ip6(boundedEnd).String()
+  }
+  if !ipnet.Contains(net.ParseIP(newManifest.Spec.Options.Pool6.Start)) || !ipnet.Contains(net.ParseIP(newManifest.Spec.Options.Pool6.End)) {
+    return errors.New("Allocation pool is outside of defined Net6 CIDR")
+  }
+  if ipam.Ip2int6(net.ParseIP(newManifest.Spec.Options.Pool6.End)).Cmp(ipam.Ip2int6(net.ParseIP(newManifest.Spec.Options.Pool6.Start))) <= 0 {
+    return errors.New("Allocation pool start:" + newManifest.Spec.Options.Pool6.Start + " is bigger than or equal to allocation pool end:" + newManifest.Spec.Options.Pool6.End)
+  }
+  //the allocation bitmask holds one bit per address, so the pool must be bounded far below a full
+  ///64 or the bitmask it backs could not be represented; reject oversized pools here, at
+  //admission time
+  if _, err := ipam.PoolSize6(net.ParseIP(newManifest.Spec.Options.Pool6.Start), net.ParseIP(newManifest.Spec.Options.Pool6.End)); err != nil {
+    return err
+  }
+  return nil
+}
+
+//GetV6RangeEnd returns the last address of the first /64 of subnet if subnet's prefix is shorter
+//than /64, or of subnet's own prefix if that prefix is already /64 or longer
+func GetV6RangeEnd(subnet *net.IPNet) (net.IP) {
+  ones, bits := subnet.Mask.Size()
+  if ones < 64 {
+    ones = 64
+  }
+  mask := net.CIDRMask(ones, bits)
+  ip := make(net.IP, len(subnet.IP.To16()))
+  copy(ip, subnet.IP.To16())
+  for i := range ip {
+    ip[i] |= ^mask[i]
+  }
+  return ip
+}
+
+func validateExclusions(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
+  exclusions := newManifest.Spec.Options.Exclusions
+  if len(exclusions) == 0 {
+    return nil
+  }
+  cidr := newManifest.Spec.Options.Cidr
+  net6 := newManifest.Spec.Options.Net6
+  if cidr == "" && net6 == "" {
+    return errors.New("Exclusion ranges cannot be defined without CIDR or Net6!")
+  }
+  for i, excl := range exclusions {
+    start := net.ParseIP(excl.StartIP)
+    end := net.ParseIP(excl.EndIP)
+    if start == nil || end == nil {
+      return errors.New("Exclusion range:" + excl.StartIP + "-" + excl.EndIP + " contains an invalid IP address")
+    }
+    ipnet, poolStart, poolEnd, err := getContainingPool(newManifest, start)
+    if err != nil {
+      return err
+    }
+    if !ipnet.Contains(start) || !ipnet.Contains(end) {
+      return errors.New("Exclusion range:" + excl.StartIP + "-" + excl.EndIP + " falls outside of the network's CIDR")
+    }
+    if ipToBigInt(end).Cmp(ipToBigInt(start)) < 0 {
+      return errors.New("Exclusion range:" + excl.StartIP + "-" + excl.EndIP + " is inverted, start is bigger than end")
+    }
+    if poolStart != "" && (ipToBigInt(start).Cmp(ipToBigInt(net.ParseIP(poolStart))) < 0 || ipToBigInt(end).Cmp(ipToBigInt(net.ParseIP(poolEnd))) > 0) {
+      return errors.New("Exclusion range:" + excl.StartIP + "-" + excl.EndIP + " falls outside of the allocation pool")
+    }
+    for _, other := range exclusions[i+1:] {
+      otherStart := net.ParseIP(other.StartIP)
+      otherEnd := net.ParseIP(other.EndIP)
+      if ipToBigInt(start).Cmp(ipToBigInt(otherEnd)) <= 0 && ipToBigInt(otherStart).Cmp(ipToBigInt(end)) <= 0 {
+        return errors.New("Exclusion ranges " + excl.StartIP + "-" + excl.EndIP + " and " + other.StartIP + "-" + other.EndIP + " overlap")
+      }
+    }
+  }
+  if opType == admissionv1.Create {
+    if err := ipam.ReserveExclusions(newManifest, exclusions); err != nil {
+      return errors.New("Could not reserve exclusion ranges in the allocation bitmask: " + err.Error())
+    }
+  }
+  return nil
+}
+
+//getContainingPool returns the CIDR, and the allocation pool boundaries, matching the IP family of ip
+func getContainingPool(newManifest *danmtypes.DanmNet, ip net.IP) (*net.IPNet, string, string, error) {
+  if ip.To4() != nil {
+    _, ipnet, err := net.ParseCIDR(newManifest.Spec.Options.Cidr)
+    if err != nil {
+      return nil, "", "", errors.New("Invalid CIDR parameter: " + newManifest.Spec.Options.Cidr)
+    }
+    return ipnet, newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End, nil
+  }
+  _, ipnet, err := net.ParseCIDR(newManifest.Spec.Options.Net6)
+  if err != nil {
+    return nil, "", "", errors.New("Invalid Net6 parameter: " + newManifest.Spec.Options.Net6)
+  }
+  return ipnet, newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End, nil
+}
+
+//ipToBigInt converts an IPv4 or IPv6 address into its numeric representation, so the two
+//families can be compared with the same big.Int-based logic
+func ipToBigInt(ip net.IP) (*big.Int) {
+  return new(big.Int).SetBytes(ip.To16())
+}
+
+//validateGateway validates the explicit Gateway/Gateway6 fields, defaulting them to the first usable
+//address of the CIDR/Net6 unless NoGateway opts the network out of having one at all. Every branch
+//below (auto-defaulted or explicit) rejects a Gateway/Gateway6 that falls inside the allocation
+//pool, so the address is kept out of IPAM's reach by validation alone - there is no separate
+//allocation bitmask reservation for it, unlike Exclusions
+func validateGateway(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
+  if newManifest.Spec.Options.NoGateway && (newManifest.Spec.Options.Gateway != "" || newManifest.Spec.Options.Gateway6 != "") {
+    return errors.New("NoGateway cannot be set together with a Gateway or Gateway6 address")
+  }
+  if err := validateGatewayV4(newManifest); err != nil {
+    return err
+  }
+  return validateGatewayV6(newManifest)
+}
+
+func validateGatewayV4(newManifest *danmtypes.DanmNet) error {
+  cidr := newManifest.Spec.Options.Cidr
+  if cidr == "" {
+    if newManifest.Spec.Options.Gateway != "" {
+      return errors.New("Gateway cannot be defined without CIDR!")
+    }
+    return nil
+  }
+  _, ipnet, err := net.ParseCIDR(cidr)
+  if err != nil {
+    return errors.New("Invalid CIDR parameter: " + cidr)
+  }
+  if newManifest.Spec.Options.Gateway == "" {
+    if newManifest.Spec.Options.NoGateway {
+      return nil
+    }
+    defaultGw := ipam.Int2ip(ipam.Ip2int(ipnet.IP) + 1)
+    if !ipnet.Contains(defaultGw) {
+      return errors.New("CIDR:" + cidr + " is too small to auto-assign a Gateway, please set one explicitly or set NoGateway")
+    }
+    if isInPool(defaultGw, newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End) {
+      return errors.New("Cannot auto-assign a Gateway for CIDR:" + cidr + ", the default address already falls inside the allocation pool. Please set Gateway or NoGateway explicitly")
+    }
+    newManifest.Spec.Options.Gateway = defaultGw.String()
+    return nil
+  }
+  gw := net.ParseIP(newManifest.Spec.Options.Gateway)
+  if gw == nil || !ipnet.Contains(gw) {
+    return errors.New("Gateway address:" + newManifest.Spec.Options.Gateway + " is not part of CIDR:" + cidr)
+  }
+  if isInPool(gw, newManifest.Spec.Options.Pool.Start, newManifest.Spec.Options.Pool.End) {
+    return errors.New("Gateway address:" + newManifest.Spec.Options.Gateway + " falls inside of the allocation pool")
+  }
+  return nil
+}
+
+func validateGatewayV6(newManifest *danmtypes.DanmNet) error {
+  net6 := newManifest.Spec.Options.Net6
+  if net6 == "" {
+    if newManifest.Spec.Options.Gateway6 != "" {
+      return errors.New("Gateway6 cannot be defined without Net6!")
+    }
+    return nil
+  }
+  _, ipnet, err := net.ParseCIDR(net6)
+  if err != nil {
+    return errors.New("Invalid Net6 parameter: " + net6)
+  }
+  if newManifest.Spec.Options.Gateway6 == "" {
+    if newManifest.Spec.Options.NoGateway {
+      return nil
+    }
+    defaultGw := ipam.Int2ip6(new(big.Int).Add(ipam.Ip2int6(ipnet.IP), big.NewInt(1)))
+    if !ipnet.Contains(defaultGw) {
+      return errors.New("Net6:" + net6 + " is too small to auto-assign a Gateway6, please set one explicitly or set NoGateway")
+    }
+    if isInPool(defaultGw, newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End) {
+      return errors.New("Cannot auto-assign a Gateway6 for Net6:" + net6 + ", the default address already falls inside the allocation pool. Please set Gateway6 or NoGateway explicitly")
+    }
+    newManifest.Spec.Options.Gateway6 = defaultGw.String()
+    return nil
+  }
+  gw := net.ParseIP(newManifest.Spec.Options.Gateway6)
+  if gw == nil || !ipnet.Contains(gw) {
+    return errors.New("Gateway6 address:" + newManifest.Spec.Options.Gateway6 + " is not part of Net6:" + net6)
+  }
+  if isInPool(gw, newManifest.Spec.Options.Pool6.Start, newManifest.Spec.Options.Pool6.End) {
+    return errors.New("Gateway6 address:" + newManifest.Spec.Options.Gateway6 + " falls inside of the allocation pool")
+  }
+  return nil
+}
+
+//isInPool returns true if ip falls within the poolStart..poolEnd range (inclusive)
+func isInPool(ip net.IP, poolStart, poolEnd string) bool {
+  if poolStart == "" || poolEnd == "" {
+    return false
+  }
+  start := net.ParseIP(poolStart)
+  end := net.ParseIP(poolEnd)
+  if start == nil || end == nil {
+    return false
+  }
+  return ipToBigInt(ip).Cmp(ipToBigInt(start)) >= 0 && ipToBigInt(ip).Cmp(ipToBigInt(end)) <= 0
+}
+
+//validateVids parses VlanRange/VxlanRange into Status.Vlans/Vxlans, the tag pool the CNI backend
+//allocates single VIDs out of (via ipam.AllocateVid) when attaching a pod, so that two pods sharing
+//the same network end up with distinct tags. Status.VlanAlloc/VxlanAlloc, the bitmask backing that
+//allocation, is rejected here on Create for the same reason a manually defined Alloc/Alloc6 is: it
+//must only ever be produced by AllocateVid/ReleaseVid, never supplied by the user
 func validateVids(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
+  if opType == admissionv1.Create && (newManifest.Status.VlanAlloc != "" || newManifest.Status.VxlanAlloc != "") {
+    return errors.New("Vlan/Vxlan allocation bitmask shall not be manually defined upon creation!")
+  }
   isVlanDefined := (newManifest.Spec.Options.Vlan!=0)
   isVxlanDefined := (newManifest.Spec.Options.Vxlan!=0)
   if isVlanDefined && isVxlanDefined {
     return errors.New("VLAN ID and VxLAN ID parameters are mutually exclusive")
   }
+  isVlanRangeDefined := newManifest.Spec.Options.VlanRange != ""
+  isVxlanRangeDefined := newManifest.Spec.Options.VxlanRange != ""
+  if isVlanRangeDefined && isVxlanRangeDefined {
+    return errors.New("VlanRange and VxlanRange parameters are mutually exclusive")
+  }
+  if isVlanDefined && isVlanRangeDefined {
+    return errors.New("Vlan and VlanRange parameters are mutually exclusive")
+  }
+  if isVxlanDefined && isVxlanRangeDefined {
+    return errors.New("Vxlan and VxlanRange parameters are mutually exclusive")
+  }
+  if isVlanRangeDefined {
+    vlans, err := parseVidRange(newManifest.Spec.Options.VlanRange, MinVlanId, MaxVlanId)
+    if err != nil {
+      return err
+    }
+    newManifest.Status.Vlans = vlans
+  }
+  if isVxlanRangeDefined {
+    vxlans, err := parseVidRange(newManifest.Spec.Options.VxlanRange, MinVxlanId, MaxVxlanId)
+    if err != nil {
+      return err
+    }
+    newManifest.Status.Vxlans = vxlans
+  }
   return nil
 }
 
+//parseVidRange parses a comma-separated list of VIDs and VID ranges (e.g. "100-200,300,400-410"),
+//rejecting values outside of min..max and any two sub-ranges that overlap. Overlaps are detected by
+//sorting the parsed sub-ranges and comparing adjacent pairs rather than enumerating every VID, since
+//a legal VxLAN range can span up to 16777215 individual tags
+func parseVidRange(vidRange string, min, max uint32) ([]danmtypes.VidRange, error) {
+  var ranges []danmtypes.VidRange
+  for _, part := range strings.Split(vidRange, ",") {
+    part = strings.TrimSpace(part)
+    if part == "" {
+      continue
+    }
+    bounds := strings.SplitN(part, "-", 2)
+    start, err := strconv.ParseUint(bounds[0], 10, 32)
+    if err != nil {
+      return nil, errors.New("Invalid VID range element:" + part)
+    }
+    end := start
+    if len(bounds) == 2 {
+      end, err = strconv.ParseUint(bounds[1], 10, 32)
+      if err != nil {
+        return nil, errors.New("Invalid VID range element:" + part)
+      }
+    }
+    if start > end {
+      return nil, errors.New("Invalid VID range:" + part + ", range start is bigger than range end")
+    }
+    if uint32(start) < min || uint32(end) > max {
+      return nil, errors.New("VID range:" + part + " is outside of the allowed " + strconv.FormatUint(uint64(min), 10) + "-" + strconv.FormatUint(uint64(max), 10) + " interval")
+    }
+    ranges = append(ranges, danmtypes.VidRange{Start: uint32(start), End: uint32(end)})
+  }
+  sort.Slice(ranges, func(i, j int) bool {
+    return ranges[i].Start < ranges[j].Start
+  })
+  for i := 1; i < len(ranges); i++ {
+    if ranges[i].Start <= ranges[i-1].End {
+      return nil, errors.New("VID ranges contain overlapping value:" + strconv.FormatUint(uint64(ranges[i].Start), 10))
+    }
+  }
+  return ranges, nil
+}
+
 func validateNetworkId(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
   if newManifest.Spec.NetworkID == "" {
     return errors.New("Spec.NetworkID mandatory parameter is missing!")
@@ -126,11 +460,105 @@ func validateTenantNetRules(oldManifest, newManifest *danmtypes.DanmNet, opType
      newManifest.Spec.Options.Vlan   != 0) {
     return errors.New("Manually configuring any one of host_device, vlan, or vxlan attributes is not allowed for TenantNetworks!")  
   }
-  if opType == admissionv1.Update && 
-    (newManifest.Spec.Options.Device  != oldManifest.Spec.Options.Device  || 
+  if opType == admissionv1.Update &&
+    (newManifest.Spec.Options.Device  != oldManifest.Spec.Options.Device  ||
      newManifest.Spec.Options.Vxlan   != oldManifest.Spec.Options.Vxlan   ||
      newManifest.Spec.Options.Vlan    != oldManifest.Spec.Options.Vlan) {
-    return errors.New("Manually changing any one of host_device, vlan, or vxlan attributes is not allowed for TenantNetworks!")  
+    return errors.New("Manually changing any one of host_device, vlan, or vxlan attributes is not allowed for TenantNetworks!")
   }
   return nil
+}
+
+//validateNetworkOverlaps rejects a ClusterNetwork/TenantNetwork manifest if its CIDR, Net6, or any of its
+//route destinations overlap another already existing ClusterNetwork or TenantNetwork, unless both networks
+//are configured on the same Device+Vlan/Vxlan and are therefore legitimately the same L2 segment
+func validateNetworkOverlaps(oldManifest, newManifest *danmtypes.DanmNet, opType admissionv1.Operation) error {
+  if (opType != admissionv1.Create && opType != admissionv1.Update) || danmClient == nil {
+    return nil
+  }
+  clusterNets, err := danmClient.DanmV1().ClusterNetworks().List(metav1.ListOptions{})
+  if err != nil {
+    return errors.New("Could not list ClusterNetworks for overlap validation:" + err.Error())
+  }
+  for _, other := range clusterNets.Items {
+    if err := checkNetworkOverlap(newManifest, &other, "ClusterNetwork"); err != nil {
+      return err
+    }
+  }
+  tenantNets, err := danmClient.DanmV1().TenantNetworks(metav1.NamespaceAll).List(metav1.ListOptions{})
+  if err != nil {
+    return errors.New("Could not list TenantNetworks for overlap validation:" + err.Error())
+  }
+  for _, other := range tenantNets.Items {
+    if err := checkNetworkOverlap(newManifest, &other, "TenantNetwork"); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func checkNetworkOverlap(newManifest, other *danmtypes.DanmNet, otherKind string) error {
+  if other.ObjectMeta.Namespace == newManifest.ObjectMeta.Namespace && other.ObjectMeta.Name == newManifest.ObjectMeta.Name {
+    return nil
+  }
+  sameL2 := newManifest.Spec.Options.Device != "" &&
+    newManifest.Spec.Options.Device == other.Spec.Options.Device &&
+    newManifest.Spec.Options.Vlan   == other.Spec.Options.Vlan   &&
+    newManifest.Spec.Options.Vxlan  == other.Spec.Options.Vxlan
+  if sameL2 {
+    return nil
+  }
+  if cidrsOverlap(newManifest.Spec.Options.Cidr, other.Spec.Options.Cidr) {
+    return errors.New("CIDR " + newManifest.Spec.Options.Cidr + " overlaps " + otherKind + " " + other.ObjectMeta.Name + " (" + other.Spec.Options.Cidr + ")")
+  }
+  if cidrsOverlap(newManifest.Spec.Options.Net6, other.Spec.Options.Net6) {
+    return errors.New("Net6 " + newManifest.Spec.Options.Net6 + " overlaps " + otherKind + " " + other.ObjectMeta.Name + " (" + other.Spec.Options.Net6 + ")")
+  }
+  for dest := range newManifest.Spec.Options.Routes {
+    if isDefaultRoute(dest) {
+      continue
+    }
+    if cidrsOverlap(dest, other.Spec.Options.Cidr) {
+      return errors.New("Route destination " + dest + " overlaps " + otherKind + " " + other.ObjectMeta.Name + " (" + other.Spec.Options.Cidr + "), would create an ambiguous next-hop")
+    }
+  }
+  for dest := range newManifest.Spec.Options.Routes6 {
+    if isDefaultRoute(dest) {
+      continue
+    }
+    if cidrsOverlap(dest, other.Spec.Options.Net6) {
+      return errors.New("Route destination " + dest + " overlaps " + otherKind + " " + other.ObjectMeta.Name + " (" + other.Spec.Options.Net6 + "), would create an ambiguous next-hop")
+    }
+  }
+  return nil
+}
+
+//cidrsOverlap returns true if a and b are both parseable, non-empty CIDRs and one contains the other's network address
+func cidrsOverlap(a, b string) bool {
+  if a == "" || b == "" {
+    return false
+  }
+  _, aNet, err := net.ParseCIDR(a)
+  if err != nil {
+    return false
+  }
+  _, bNet, err := net.ParseCIDR(b)
+  if err != nil {
+    return false
+  }
+  return aNet.Contains(bNet.IP) || bNet.Contains(aNet.IP)
+}
+
+//isDefaultRoute returns true if dest is a zero-length route destination such as 0.0.0.0/0 or ::/0.
+//A default route just describes how a gateway is reached, not an address range that could actually
+//collide with another network's CIDR, so it must be excluded from overlap detection - otherwise a
+//legacy manifest that still expresses its Gateway as a 0.0.0.0/0 Routes entry would overlap every
+//other network that has a CIDR at all
+func isDefaultRoute(dest string) bool {
+  _, ipnet, err := net.ParseCIDR(dest)
+  if err != nil {
+    return false
+  }
+  ones, _ := ipnet.Mask.Size()
+  return ones == 0
 }
\ No newline at end of file
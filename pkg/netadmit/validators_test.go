@@ -0,0 +1,111 @@
+package netadmit
+
+import (
+  "net"
+  "testing"
+
+  admissionv1 "k8s.io/api/admission/v1beta1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+func TestIsInPool(t *testing.T) {
+  cases := []struct {
+    ip       string
+    start    string
+    end      string
+    expected bool
+  }{
+    {"10.0.0.5", "10.0.0.1", "10.0.0.10", true},
+    {"10.0.0.11", "10.0.0.1", "10.0.0.10", false},
+    {"10.0.0.1", "10.0.0.1", "10.0.0.10", true},
+    {"10.0.0.10", "10.0.0.1", "10.0.0.10", true},
+  }
+  for _, c := range cases {
+    got := isInPool(net.ParseIP(c.ip), c.start, c.end)
+    if got != c.expected {
+      t.Errorf("isInPool(%s, %s, %s) = %v, want %v", c.ip, c.start, c.end, got, c.expected)
+    }
+  }
+}
+
+//TestValidateGatewayRejectsDefaultCollidingWithExistingPool guards against the auto-defaulted
+//Gateway silently landing inside an allocation pool that was already persisted before this network
+//started using the Gateway feature (e.g. an Update on a pre-existing network)
+func TestValidateGatewayRejectsDefaultCollidingWithExistingPool(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      Options: danmtypes.DanmNetOption{
+        Cidr: "10.0.0.0/24",
+        Pool: danmtypes.IpPool{Start: "10.0.0.1", End: "10.0.0.254"},
+      },
+    },
+  }
+  err := validateGateway(manifest, manifest, admissionv1.Update)
+  if err == nil {
+    t.Fatal("expected an error when the default Gateway would collide with an already persisted allocation pool, got nil")
+  }
+}
+
+func TestValidateGatewayDefaultsClearOfFreshPool(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      Options: danmtypes.DanmNetOption{
+        Cidr: "10.0.0.0/24",
+      },
+    },
+  }
+  if err := validateAllocationPool(manifest, manifest, admissionv1.Create); err != nil {
+    t.Fatalf("unexpected error defaulting the allocation pool: %v", err)
+  }
+  if err := validateGateway(manifest, manifest, admissionv1.Create); err != nil {
+    t.Fatalf("unexpected error defaulting the gateway: %v", err)
+  }
+  if manifest.Spec.Options.Gateway != "10.0.0.1" {
+    t.Errorf("expected default Gateway 10.0.0.1, got %s", manifest.Spec.Options.Gateway)
+  }
+  if manifest.Spec.Options.Pool.Start != "10.0.0.2" {
+    t.Errorf("expected Pool.Start to skip the reserved Gateway address, got %s", manifest.Spec.Options.Pool.Start)
+  }
+}
+
+//TestValidateGatewayAcceptsExplicitGatewayAtDefaultedPoolStart guards against an explicit Gateway
+//equal to the conventional net+1 address being rejected once the allocation pool auto-defaults,
+//even though leaving Gateway blank (and thus landing on the very same address) succeeds
+func TestValidateGatewayAcceptsExplicitGatewayAtDefaultedPoolStart(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      Options: danmtypes.DanmNetOption{
+        Cidr:    "10.0.0.0/24",
+        Gateway: "10.0.0.1",
+      },
+    },
+  }
+  if err := validateAllocationPool(manifest, manifest, admissionv1.Create); err != nil {
+    t.Fatalf("unexpected error defaulting the allocation pool: %v", err)
+  }
+  if err := validateGateway(manifest, manifest, admissionv1.Create); err != nil {
+    t.Fatalf("unexpected error validating an explicit Gateway at the default address: %v", err)
+  }
+  if manifest.Spec.Options.Pool.Start != "10.0.0.2" {
+    t.Errorf("expected Pool.Start to skip the explicit Gateway address, got %s", manifest.Spec.Options.Pool.Start)
+  }
+}
+
+//TestValidateAllocationPoolV6DefaultsUsableWindowOnFullSubnet guards against the defaulted Pool6
+//spanning a whole /64, which the allocation bitmask cannot represent - the defaulted window must be
+//bounded instead, so a plain dual-stack network without an explicit Pool6 is actually admittable
+func TestValidateAllocationPoolV6DefaultsUsableWindowOnFullSubnet(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      Options: danmtypes.DanmNetOption{
+        Net6: "2001:db8::/64",
+      },
+    },
+  }
+  if err := validateAllocationPoolV6(manifest, manifest, admissionv1.Create); err != nil {
+    t.Fatalf("unexpected error defaulting Pool6 on a plain /64 Net6: %v", err)
+  }
+  if manifest.Spec.Options.Pool6.Start == "" || manifest.Spec.Options.Pool6.End == "" {
+    t.Fatal("expected Pool6.Start/End to be defaulted")
+  }
+}
@@ -0,0 +1,77 @@
+package netadmit
+
+import (
+  "testing"
+
+  admissionv1 "k8s.io/api/admission/v1beta1"
+  danmtypes "github.com/nokia/danm/crd/apis/danm/v1"
+)
+
+func TestParseVidRange(t *testing.T) {
+  ranges, err := parseVidRange("100-200,300,400-410", MinVlanId, MaxVlanId)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(ranges) != 3 {
+    t.Fatalf("expected 3 parsed sub-ranges, got %d", len(ranges))
+  }
+  if ranges[0].Start != 100 || ranges[0].End != 200 {
+    t.Errorf("unexpected first range: %+v", ranges[0])
+  }
+  if ranges[1].Start != 300 || ranges[1].End != 300 {
+    t.Errorf("unexpected second range: %+v", ranges[1])
+  }
+}
+
+func TestParseVidRangeRejectsOverlap(t *testing.T) {
+  if _, err := parseVidRange("100-200,150-160", MinVlanId, MaxVlanId); err == nil {
+    t.Fatal("expected an error for overlapping sub-ranges")
+  }
+}
+
+func TestParseVidRangeRejectsOutOfBounds(t *testing.T) {
+  if _, err := parseVidRange("1-5000", MinVlanId, MaxVlanId); err == nil {
+    t.Fatal("expected an error for a VLAN range exceeding MaxVlanId")
+  }
+}
+
+//TestParseVidRangeDoesNotEnumerate ensures a full-width VxLAN range parses without materializing
+//one entry per tag, which would allocate tens of millions of slice/map entries per request
+func TestParseVidRangeDoesNotEnumerate(t *testing.T) {
+  ranges, err := parseVidRange("1-16777215", MinVxlanId, MaxVxlanId)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(ranges) != 1 {
+    t.Fatalf("expected the full-width range to collapse to a single VidRange, got %d entries", len(ranges))
+  }
+}
+
+//TestTenantNetMappingParsesVidRange guards against VlanRange/VxlanRange being silently ignored on a
+//TenantNetwork: validateVids must be part of TenantNetMapping, since tenant-scoped VID pools are the
+//whole point of the VlanRange/VxlanRange feature
+func TestTenantNetMappingParsesVidRange(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Spec: danmtypes.DanmNetSpec{
+      NetworkID: "tenant-net",
+      Options:   danmtypes.DanmNetOption{VlanRange: "100-200"},
+    },
+  }
+  for _, validator := range TenantNetMapping {
+    if err := validator(manifest, manifest, admissionv1.Create); err != nil {
+      t.Fatalf("unexpected error running TenantNetMapping: %v", err)
+    }
+  }
+  if len(manifest.Status.Vlans) != 1 || manifest.Status.Vlans[0].Start != 100 || manifest.Status.Vlans[0].End != 200 {
+    t.Fatalf("expected TenantNetMapping to parse VlanRange into Status.Vlans, got %+v", manifest.Status.Vlans)
+  }
+}
+
+func TestValidateVidsRejectsManualAllocBitmaskOnCreate(t *testing.T) {
+  manifest := &danmtypes.DanmNet{
+    Status: danmtypes.DanmNetStatus{VlanAlloc: "QQ=="},
+  }
+  if err := validateVids(manifest, manifest, admissionv1.Create); err == nil {
+    t.Fatal("expected an error when Status.VlanAlloc is manually defined upon creation")
+  }
+}